@@ -0,0 +1,108 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Dadido3/configdb/tree"
+)
+
+// Config combines one or more File backends into a single merged configuration tree.
+//
+// Files are merged in the order they are given, so later files overwrite values set by earlier ones.
+type Config struct {
+	mutex sync.RWMutex
+
+	files []File
+	tree  tree.Node
+
+	watchMutex     sync.RWMutex
+	watchers       []*subscription
+	reloadDebounce time.Duration
+	reloadTimer    *time.Timer
+}
+
+// NewConfig creates a new Config from the given list of files.
+//
+// Files are loaded and merged in the order they are given, so later files take precedence over earlier
+// ones.
+func NewConfig(files []File) (*Config, error) {
+	c := &Config{
+		files: files,
+		tree:  tree.Node{},
+	}
+
+	merged, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.tree = merged
+
+	return c, nil
+}
+
+// load reads and merges every backing file into a single tree.Node.
+func (c *Config) load() (tree.Node, error) {
+	merged := tree.Node{}
+
+	for _, f := range c.files {
+		n, err := f.Load()
+		if err != nil {
+			return nil, err
+		}
+		merged.Merge(n)
+	}
+
+	return merged, nil
+}
+
+// Get reads the element at the given path into out, which must be a non-nil pointer to a struct,
+// map, slice/array, or supported primitive.
+func (c *Config) Get(path string, out interface{}) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.tree.GetStruct(path, out)
+}
+
+// Set writes value to the path in the in-memory tree, and notifies any matching Watch subscribers.
+//
+// This does not persist the change to any backing File.
+func (c *Config) Set(path string, value interface{}) error {
+	c.mutex.Lock()
+	old := cloneNode(c.tree)
+	err := c.tree.Set(path, value)
+	new := cloneNode(c.tree) // Clone while still under the lock, so notify can diff it without racing the next Set/Reload.
+	c.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	c.notify(old, new)
+
+	return nil
+}
+
+// Close releases any resources held by the Config, including stopping all Watch subscriptions.
+func (c *Config) Close() error {
+	c.watchMutex.Lock()
+	watchers := c.watchers
+	c.watchers = nil
+	if c.reloadTimer != nil {
+		c.reloadTimer.Stop()
+		c.reloadTimer = nil
+	}
+	c.watchMutex.Unlock()
+
+	for _, sub := range watchers {
+		sub.stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import "github.com/Dadido3/configdb/tree"
+
+// File represents a single configuration source backing a Config.
+//
+// Implementations load their content into a tree.Node, and serialize a tree.Node back into their
+// underlying storage.
+type File interface {
+	// Load reads the current content of the file and returns it as a tree.Node.
+	Load() (tree.Node, error)
+
+	// Save writes the given tree.Node back to the file.
+	Save(tree.Node) error
+}
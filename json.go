@@ -0,0 +1,69 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Dadido3/configdb/tree"
+)
+
+// jsonFile is a File backend that loads and saves its content as JSON.
+type jsonFile struct {
+	path string
+}
+
+// UseJSONFile returns a File backend that reads and writes the configuration as JSON at the given
+// path.
+func UseJSONFile(path string) File {
+	return &jsonFile{path: path}
+}
+
+// Load reads the JSON file and converts it into a tree.Node.
+func (f *jsonFile) Load() (tree.Node, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	dec.UseNumber() // Keep integers from being rounded through float64.
+
+	var generic map[string]interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	converted, err := tree.FromGeneric(generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	node := converted.(tree.Node)
+	if err := node.Check(); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	return node, nil
+}
+
+// Save writes the given tree.Node back to the JSON file.
+func (f *jsonFile) Save(n tree.Node) error {
+	generic, err := tree.ToGeneric(n)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(generic, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.path, data, 0644)
+}
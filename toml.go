@@ -0,0 +1,65 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Dadido3/configdb/tree"
+)
+
+// tomlFile is a File backend that loads and saves its content as TOML.
+type tomlFile struct {
+	path string
+}
+
+// UseTOMLFile returns a File backend that reads and writes the configuration as TOML at the given
+// path.
+func UseTOMLFile(path string) File {
+	return &tomlFile{path: path}
+}
+
+// Load reads the TOML file and converts it into a tree.Node.
+//
+// Tables become tree.Node, arrays of tables become []tree.Node, and scalars/arrays map onto the
+// bool/string/Number cases tree.Node.Set already handles. Mixed-type arrays are rejected here, by
+// tree.FromGeneric, so the failure surfaces at load time rather than later at Get.
+func (f *tomlFile) Load() (tree.Node, error) {
+	var generic map[string]interface{}
+	if _, err := toml.DecodeFile(f.path, &generic); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	converted, err := tree.FromGeneric(generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	node := converted.(tree.Node)
+	if err := node.Check(); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", f.path, err)
+	}
+
+	return node, nil
+}
+
+// Save writes the given tree.Node back to the TOML file, preserving table nesting.
+func (f *tomlFile) Save(n tree.Node) error {
+	generic, err := tree.ToGeneric(n)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(generic)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/configdb/tree"
+)
+
+// TestTOMLSaveWholeNumberFloat makes sure a whole-number float (e.g. 3.0) survives a Save/Load round
+// trip as a float rather than being narrowed to an integer.
+func TestTOMLSaveWholeNumberFloat(t *testing.T) {
+	ratio, err := tree.NumberCreate(3.0)
+	if err != nil {
+		t.Fatalf("NumberCreate() failed: %v", err)
+	}
+
+	n := tree.Node{"ratio": ratio}
+
+	path := filepath.Join(t.TempDir(), "roundtrip.toml")
+	f := UseTOMLFile(path)
+	if err := f.Save(n); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	got, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	num, ok := got.Get("ratio").(tree.Number)
+	if !ok {
+		t.Fatalf("ratio has unexpected type %T", got.Get("ratio"))
+	}
+	if !num.IsFloat() {
+		t.Errorf("ratio = %q, want a float-formatted Number", num)
+	}
+	if f, err := num.Float64(); err != nil || f != 3.0 {
+		t.Errorf("ratio.Float64() = %v, %v, want 3.0, nil", f, err)
+	}
+}
+
+func TestTOMLLoad(t *testing.T) {
+	f := UseTOMLFile(filepath.Join(".", "testfiles", "toml", "a.toml"))
+
+	node, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := node.GetString("title", ""); got != "Example" {
+		t.Errorf("title = %q, want %q", got, "Example")
+	}
+
+	if got := node.GetInt64("subnode.b", 0); got != 1 {
+		t.Errorf("subnode.b = %d, want %d", got, 1)
+	}
+}
+
+func TestTOMLLoadArrayOfTables(t *testing.T) {
+	f := UseTOMLFile(filepath.Join(".", "testfiles", "toml", "a.toml"))
+
+	node, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	items, ok := node.Get("subnode.items").([]tree.Node)
+	if !ok {
+		t.Fatalf("subnode.items has unexpected type %T", node.Get("subnode.items"))
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(subnode.items) = %d, want %d", len(items), 2)
+	}
+
+	if got := items[0].GetString("name", ""); got != "foo" {
+		t.Errorf("subnode.items[0].name = %q, want %q", got, "foo")
+	}
+	if got := items[1].GetString("name", ""); got != "bar" {
+		t.Errorf("subnode.items[1].name = %q, want %q", got, "bar")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FromGeneric converts a value as produced by a generic decoder (the shapes you get from
+// encoding/json or BurntSushi/toml when decoding into interface{}) into the representation used by
+// Node.
+//
+// Maps become Node, and slices become one of []Node, []bool, []string or []Number depending on their
+// element type. Mixed-type slices are rejected here, so callers of a File backend see the failure at
+// load time instead of running into it later when reading a value back out with Node.Get.
+func FromGeneric(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		node := Node{}
+		for key, val := range v {
+			converted, err := FromGeneric(val)
+			if err != nil {
+				return nil, err
+			}
+			node[key] = converted
+		}
+		return node, nil
+
+	case []interface{}:
+		return fromGenericSlice(v)
+
+	case []map[string]interface{}: // BurntSushi/toml decodes an array of tables this way.
+		generic := make([]interface{}, len(v))
+		for i, val := range v {
+			generic[i] = val
+		}
+		return fromGenericSlice(generic)
+
+	case bool, string:
+		return v, nil
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return NumberCreate(i)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return NumberCreate(f)
+
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return NumberCreate(v)
+
+	case nil:
+		return nil, nil
+
+	default:
+		return nil, ErrUnexpectedType{"", fmt.Sprintf("%T", v), ""}
+	}
+}
+
+// fromGenericSlice converts a generic slice into one of the homogeneous array types supported by Node.
+func fromGenericSlice(v []interface{}) (interface{}, error) {
+	if len(v) == 0 {
+		return []string{}, nil // An empty array has no discernible type, default to a string array.
+	}
+
+	converted := make([]interface{}, len(v))
+	for i, val := range v {
+		c, err := FromGeneric(val)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = c
+	}
+
+	switch converted[0].(type) {
+	case Node:
+		nodes := make([]Node, len(converted))
+		for i, c := range converted {
+			node, ok := c.(Node)
+			if !ok {
+				return nil, ErrMixedArray{"", fmt.Sprintf("%T", c), "node"}
+			}
+			nodes[i] = node
+		}
+		return nodes, nil
+
+	case bool:
+		bools := make([]bool, len(converted))
+		for i, c := range converted {
+			b, ok := c.(bool)
+			if !ok {
+				return nil, ErrMixedArray{"", fmt.Sprintf("%T", c), "bool"}
+			}
+			bools[i] = b
+		}
+		return bools, nil
+
+	case string:
+		strs := make([]string, len(converted))
+		for i, c := range converted {
+			s, ok := c.(string)
+			if !ok {
+				return nil, ErrMixedArray{"", fmt.Sprintf("%T", c), "string"}
+			}
+			strs[i] = s
+		}
+		return strs, nil
+
+	case Number:
+		nums := make([]Number, len(converted))
+		for i, c := range converted {
+			num, ok := c.(Number)
+			if !ok {
+				return nil, ErrMixedArray{"", fmt.Sprintf("%T", c), "number"}
+			}
+			nums[i] = num
+		}
+		return nums, nil
+
+	default:
+		return nil, ErrUnexpectedType{"", fmt.Sprintf("%T", converted[0]), ""}
+	}
+}
+
+// ToGeneric converts a Node, or any value it may contain, back into the generic shapes most encoders
+// (encoding/json, BurntSushi/toml, ...) expect when marshaling: map[string]interface{},
+// []interface{}, bool, string, and int64/float64.
+func ToGeneric(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case Node:
+		generic := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			converted, err := ToGeneric(val)
+			if err != nil {
+				return nil, err
+			}
+			generic[key] = converted
+		}
+		return generic, nil
+
+	case []Node:
+		generic := make([]interface{}, len(v))
+		for i, val := range v {
+			converted, err := ToGeneric(val)
+			if err != nil {
+				return nil, err
+			}
+			generic[i] = converted
+		}
+		return generic, nil
+
+	case []bool:
+		generic := make([]interface{}, len(v))
+		for i, val := range v {
+			generic[i] = val
+		}
+		return generic, nil
+
+	case []string:
+		generic := make([]interface{}, len(v))
+		for i, val := range v {
+			generic[i] = val
+		}
+		return generic, nil
+
+	case []Number:
+		generic := make([]interface{}, len(v))
+		for i, val := range v {
+			converted, err := ToGeneric(val)
+			if err != nil {
+				return nil, err
+			}
+			generic[i] = converted
+		}
+		return generic, nil
+
+	case bool, string:
+		return v, nil
+
+	case Number:
+		if v.IsFloat() {
+			return v.Float64()
+		}
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+
+	default:
+		return nil, ErrUnexpectedType{"", fmt.Sprintf("%T", v), ""}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import "fmt"
+
+// ErrElementNotFound indicates that there is no element at the given path.
+type ErrElementNotFound struct {
+	Path string
+}
+
+func (e ErrElementNotFound) Error() string {
+	return fmt.Sprintf("no element found at path %q", e.Path)
+}
+
+// ErrPathInsideValue indicates that the path points inside a value, which doesn't have any children.
+type ErrPathInsideValue struct {
+	Path string
+}
+
+func (e ErrPathInsideValue) Error() string {
+	return fmt.Sprintf("path %q points inside a value", e.Path)
+}
+
+// ErrUnexpectedType indicates that the element at the given path doesn't have the expected type.
+//
+// Want is left empty when there is no single expected type to name.
+type ErrUnexpectedType struct {
+	Path string
+	Got  string
+	Want string
+}
+
+func (e ErrUnexpectedType) Error() string {
+	if e.Want == "" {
+		return fmt.Sprintf("unexpected type %s at path %q", e.Got, e.Path)
+	}
+	return fmt.Sprintf("unexpected type %s at path %q, want %s", e.Got, e.Path, e.Want)
+}
+
+// ErrMixedArray indicates that an array contains elements of more than one type, which Node can't
+// represent.
+type ErrMixedArray struct {
+	Path string
+	Got  string
+	Want string
+}
+
+func (e ErrMixedArray) Error() string {
+	return fmt.Sprintf("array at path %q contains mixed types, got %s, want %s", e.Path, e.Got, e.Want)
+}
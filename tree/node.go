@@ -7,6 +7,7 @@ package tree
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -52,7 +53,7 @@ func (n Node) Set(path string, element interface{}) error {
 	var newElement interface{}
 
 	switch v := element.(type) {
-	case Node, bool, string:
+	case Node, bool, string, Number:
 		newElement = v
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
 		result, err := NumberCreate(v)
@@ -60,11 +61,16 @@ func (n Node) Set(path string, element interface{}) error {
 			return err
 		}
 		newElement = result
-	case []Node, []bool, []string, []Number: // TODO: Add more array types (Special case: []byte). Also array of arrays
+	case []Node, []bool, []string, []Number:
 		newElement = v
-	// TODO: Handle any structure, and split it into its base types/arrays/nodes
 	default:
-		return ErrUnexpectedType{"", fmt.Sprintf("%T", v), ""}
+		// Any other well-typed struct, map or slice is split into its base types/arrays/nodes via
+		// reflection.
+		result, err := encodeValue(reflect.ValueOf(v))
+		if err != nil {
+			return err
+		}
+		newElement = result
 	}
 
 	pathElements := PathSplit(path)
@@ -108,9 +114,6 @@ func (n Node) Get(path string) interface{} {
 	return result
 }
 
-// TODO: Add GetStruct method
-// TODO: Add GetArray* methods
-
 // GetBoolOrError returns the bool at the given path, or an error if it doesn't exist.
 func (n Node) GetBoolOrError(path string) (bool, error) {
 	inter, err := n.GetOrError(path)
@@ -310,13 +313,14 @@ func (n Node) Check() error {
 					return err
 				}
 			}
-		case []Node: // TODO: Array values, arrays and other things (Use reflect package)
+		case []Node:
 			for i, child := range v {
 				err := recursive(child, PathJoin(path, fmt.Sprint(i))) // Pseudo path for array elements, not really a valid path
 				if err != nil {
 					return err
 				}
 			}
+		case []bool, []string, []Number:
 		case bool, string, Number:
 		default:
 			return ErrUnexpectedType{path, fmt.Sprintf("%T", v), ""}
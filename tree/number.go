@@ -0,0 +1,83 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number is a numeric value inside a Node.
+//
+// It stores its value as text rather than as float64 or int64, so integers round-trip through a Node
+// without losing precision to a float conversion.
+type Number string
+
+// NumberCreate converts a Go numeric value into a Number.
+func NumberCreate(v interface{}) (Number, error) {
+	switch v := v.(type) {
+	case int:
+		return Number(strconv.FormatInt(int64(v), 10)), nil
+	case int8:
+		return Number(strconv.FormatInt(int64(v), 10)), nil
+	case int16:
+		return Number(strconv.FormatInt(int64(v), 10)), nil
+	case int32:
+		return Number(strconv.FormatInt(int64(v), 10)), nil
+	case int64:
+		return Number(strconv.FormatInt(v, 10)), nil
+	case uint:
+		return Number(strconv.FormatUint(uint64(v), 10)), nil
+	case uint8:
+		return Number(strconv.FormatUint(uint64(v), 10)), nil
+	case uint16:
+		return Number(strconv.FormatUint(uint64(v), 10)), nil
+	case uint32:
+		return Number(strconv.FormatUint(uint64(v), 10)), nil
+	case uint64:
+		return Number(strconv.FormatUint(v, 10)), nil
+	case float32:
+		return numberFromFloat(float64(v)), nil
+	case float64:
+		return numberFromFloat(v), nil
+	default:
+		return "", fmt.Errorf("%T is not a supported numeric type", v)
+	}
+}
+
+// numberFromFloat formats v, making sure the result always carries a '.' or exponent even when v is a
+// whole number. This preserves v's float origin across a round trip: IsFloat and Int64 use the presence
+// of that marker to tell an int-valued Number (e.g. from NumberCreate(3)) apart from a whole-number float
+// (e.g. from NumberCreate(3.0)), so the latter isn't silently narrowed back to an int.
+func numberFromFloat(v float64) Number {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEnN") { // No fractional/exponent marker, and not Inf/NaN.
+		s += ".0"
+	}
+	return Number(s)
+}
+
+// IsFloat reports whether the number was created from a Go float and should stay a float across a
+// round trip, even if its value happens to be a whole number.
+func (n Number) IsFloat() bool {
+	return strings.ContainsAny(string(n), ".eEnN") // "nN" catches +Inf, -Inf and NaN.
+}
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// String returns the textual representation of the number.
+func (n Number) String() string {
+	return string(n)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import "testing"
+
+func TestNumberCreateWholeFloatRoundTrip(t *testing.T) {
+	n, err := NumberCreate(3.0)
+	if err != nil {
+		t.Fatalf("NumberCreate() failed: %v", err)
+	}
+
+	if !n.IsFloat() {
+		t.Errorf("IsFloat() = false for %q, want true", n)
+	}
+
+	generic, err := ToGeneric(n)
+	if err != nil {
+		t.Fatalf("ToGeneric() failed: %v", err)
+	}
+	if _, ok := generic.(float64); !ok {
+		t.Errorf("ToGeneric() = %v (%T), want float64", generic, generic)
+	}
+}
+
+func TestNumberCreateIntNotFloat(t *testing.T) {
+	n, err := NumberCreate(3)
+	if err != nil {
+		t.Fatalf("NumberCreate() failed: %v", err)
+	}
+
+	if n.IsFloat() {
+		t.Errorf("IsFloat() = true for %q, want false", n)
+	}
+
+	generic, err := ToGeneric(n)
+	if err != nil {
+		t.Fatalf("ToGeneric() failed: %v", err)
+	}
+	if _, ok := generic.(int64); !ok {
+		t.Errorf("ToGeneric() = %v (%T), want int64", generic, generic)
+	}
+}
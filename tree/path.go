@@ -0,0 +1,29 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import "strings"
+
+// PathSeparator is the character that separates the elements of a path.
+const PathSeparator = "."
+
+// PathSplit splits a path into its individual elements.
+//
+// Leading and trailing separators are ignored, so both "a.b.c" and ".a.b.c" split into the same
+// elements.
+func PathSplit(path string) []string {
+	trimmed := strings.Trim(path, PathSeparator)
+	if trimmed == "" {
+		return nil
+	}
+
+	return strings.Split(trimmed, PathSeparator)
+}
+
+// PathJoin joins the given path elements into a single path.
+func PathJoin(elements ...string) string {
+	return strings.Join(elements, PathSeparator)
+}
@@ -0,0 +1,525 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structTag is the struct tag key used to map Go struct fields to Node children.
+const structTag = "cdb"
+
+// SetStruct encodes v and writes it to the given path, creating any nodes along the way.
+//
+// v may be a struct, map[string]T, slice/array, or a pointer to one of these (or a supported
+// primitive). Fields are mapped according to their "cdb" struct tag, the same way json.Marshal uses
+// "json". This is what Node.Set falls back to for any type it doesn't recognize directly.
+func (n Node) SetStruct(path string, v interface{}) error {
+	return n.Set(path, v)
+}
+
+// GetStruct reads the element at the given path and decodes it into out, which must be a non-nil
+// pointer to a struct, map, slice/array, or supported primitive.
+func (n Node) GetStruct(path string, out interface{}) error {
+	element, err := n.GetOrError(path)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrUnexpectedType{path, fmt.Sprintf("%T", out), "non-nil pointer"}
+	}
+
+	return decodeValue(element, rv.Elem(), path)
+}
+
+// tagOptions holds the parsed options of a struct tag, e.g. "omitempty" in `cdb:"name,omitempty"`.
+type tagOptions struct {
+	omitempty bool
+	raw       bool // Encode a []byte field as a raw array of numbers instead of a base64 string.
+}
+
+// parseTag splits a struct tag into its name and options.
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+
+	opts := tagOptions{}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			opts.omitempty = true
+		case "raw":
+			opts.raw = true
+		}
+	}
+
+	return parts[0], opts
+}
+
+// encodeValue converts rv into one of the types Node.Set accepts: Node, bool, string, Number, []Node,
+// []bool, []string or []Number.
+func encodeValue(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(rv.Elem())
+
+	case reflect.Bool:
+		return rv.Bool(), nil
+
+	case reflect.String:
+		return rv.String(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NumberCreate(rv.Interface())
+
+	case reflect.Struct:
+		return encodeStruct(rv)
+
+	case reflect.Map:
+		return encodeMap(rv)
+
+	case reflect.Slice, reflect.Array:
+		return encodeSlice(rv, false)
+
+	default:
+		return nil, ErrUnexpectedType{"", rv.Type().String(), ""}
+	}
+}
+
+// encodeStruct encodes a struct value into a Node, honoring cdb tags, omitempty, "-" and embedded
+// struct promotion.
+func encodeStruct(rv reflect.Value) (Node, error) {
+	node := Node{}
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // Unexported field.
+		}
+
+		name, opts := parseTag(field.Tag.Get(structTag))
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			target := fv
+			if target.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					continue
+				}
+				target = target.Elem()
+			}
+			if target.Kind() == reflect.Struct {
+				embedded, err := encodeStruct(target)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range embedded {
+					node[k] = v
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if opts.omitempty && fv.IsZero() {
+			continue
+		}
+
+		var encoded interface{}
+		var err error
+		if isByteSlice(fv) {
+			encoded, err = encodeSlice(fv, opts.raw)
+		} else {
+			encoded, err = encodeValue(fv)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if encoded == nil {
+			continue
+		}
+
+		node[name] = encoded
+	}
+
+	return node, nil
+}
+
+// encodeMap encodes a map[string]T value into a Node.
+func encodeMap(rv reflect.Value) (Node, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, ErrUnexpectedType{"", rv.Type().String(), "map[string]T"}
+	}
+
+	node := Node{}
+	iter := rv.MapRange()
+	for iter.Next() {
+		encoded, err := encodeValue(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		node[iter.Key().String()] = encoded
+	}
+
+	return node, nil
+}
+
+// isByteSlice reports whether rv is a []byte or [N]byte.
+func isByteSlice(rv reflect.Value) bool {
+	return (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() == reflect.Uint8
+}
+
+// encodeSlice encodes a slice or array value. []byte becomes a base64 string, unless raw is set, in
+// which case it becomes a raw []Number array instead (select this with the "raw" tag option, e.g.
+// `cdb:"name,raw"`). Other slices become whichever of []Node/[]bool/[]string/[]Number matches their
+// (encoded) element type. A slice of slices is encoded as []Node, with each inner array stored as a
+// Node keyed by its index, since Node only supports one level of homogeneous array. The exception is a
+// slice of []byte: each inner []byte is already a base64 string, so the result is a []string.
+func encodeSlice(rv reflect.Value, raw bool) (interface{}, error) {
+	if isByteSlice(rv) {
+		if raw {
+			nums := make([]Number, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				n, err := NumberCreate(rv.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				nums[i] = n
+			}
+			return nums, nil
+		}
+
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+
+	if rv.Len() == 0 {
+		return []string{}, nil // An empty array has no discernible type, default to a string array.
+	}
+
+	encoded := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v, err := encodeValue(rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = v
+	}
+
+	switch encoded[0].(type) {
+	case Node:
+		nodes := make([]Node, len(encoded))
+		for i, v := range encoded {
+			node, ok := v.(Node)
+			if !ok {
+				return nil, ErrMixedArray{"", fmt.Sprintf("%T", v), "node"}
+			}
+			nodes[i] = node
+		}
+		return nodes, nil
+
+	case bool:
+		out := make([]bool, len(encoded))
+		for i, v := range encoded {
+			out[i] = v.(bool)
+		}
+		return out, nil
+
+	case string:
+		out := make([]string, len(encoded))
+		for i, v := range encoded {
+			out[i] = v.(string)
+		}
+		return out, nil
+
+	case Number:
+		out := make([]Number, len(encoded))
+		for i, v := range encoded {
+			out[i] = v.(Number)
+		}
+		return out, nil
+
+	case []bool, []string, []Number, []Node:
+		nodes := make([]Node, len(encoded))
+		for i, v := range encoded {
+			inner := Node{}
+			iv := reflect.ValueOf(v)
+			for j := 0; j < iv.Len(); j++ {
+				inner[strconv.Itoa(j)] = iv.Index(j).Interface()
+			}
+			nodes[i] = inner
+		}
+		return nodes, nil
+
+	default:
+		return nil, ErrUnexpectedType{"", fmt.Sprintf("%T", encoded[0]), ""}
+	}
+}
+
+// decodeValue decodes element from the tree into rv, which must be addressable and settable.
+func decodeValue(element interface{}, rv reflect.Value, path string) error {
+	if element == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(element, rv.Elem(), path)
+
+	case reflect.Bool:
+		v, ok := element.(bool)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "bool"}
+		}
+		rv.SetBool(v)
+
+	case reflect.String:
+		v, ok := element.(string)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "string"}
+		}
+		rv.SetString(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, ok := element.(Number)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "number"}
+		}
+		i, err := num.Int64()
+		if err != nil {
+			return ErrUnexpectedType{path, "non-integer number", "integer"}
+		}
+		rv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, ok := element.(Number)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "number"}
+		}
+		i, err := num.Int64()
+		if err != nil {
+			return ErrUnexpectedType{path, "non-integer number", "integer"}
+		}
+		rv.SetUint(uint64(i))
+
+	case reflect.Float32, reflect.Float64:
+		num, ok := element.(Number)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "number"}
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return ErrUnexpectedType{path, "non-numeric number", "float"}
+		}
+		rv.SetFloat(f)
+
+	case reflect.Struct:
+		node, ok := element.(Node)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "node"}
+		}
+		return decodeStruct(node, rv, path)
+
+	case reflect.Map:
+		node, ok := element.(Node)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "node"}
+		}
+		return decodeMap(node, rv, path)
+
+	case reflect.Slice, reflect.Array:
+		return decodeSlice(element, rv, path)
+
+	default:
+		return ErrUnexpectedType{path, rv.Type().String(), ""}
+	}
+
+	return nil
+}
+
+// decodeStruct decodes node into the struct value rv, honoring cdb tags and embedded struct
+// promotion.
+func decodeStruct(node Node, rv reflect.Value, path string) error {
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // Unexported field.
+		}
+
+		name, _ := parseTag(field.Tag.Get(structTag))
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			target := fv
+			if target.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+				target = target.Elem()
+			}
+			if target.Kind() == reflect.Struct {
+				if err := decodeStruct(node, target, path); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		child, ok := node[name]
+		if !ok {
+			continue
+		}
+
+		if err := decodeValue(child, fv, PathJoin(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeMap decodes node into the map[string]T value rv.
+func decodeMap(node Node, rv reflect.Value, path string) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return ErrUnexpectedType{path, rv.Type().String(), "map[string]T"}
+	}
+
+	out := reflect.MakeMapWithSize(rv.Type(), len(node))
+	for k, v := range node {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := decodeValue(v, elem, PathJoin(path, k)); err != nil {
+			return err
+		}
+		out.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+	}
+
+	rv.Set(out)
+	return nil
+}
+
+// decodeSlice decodes element into the slice/array value rv. A []byte field accepts either a base64
+// string or a raw []Number array (whichever encodeSlice produced), and a []Node keyed by index decodes
+// into a slice of slices, the inverse of encodeSlice.
+func decodeSlice(element interface{}, rv reflect.Value, path string) error {
+	elemType := rv.Type().Elem()
+
+	if elemType.Kind() == reflect.Uint8 {
+		switch element := element.(type) {
+		case string:
+			b, err := base64.StdEncoding.DecodeString(element)
+			if err != nil {
+				return ErrUnexpectedType{path, "string", "base64 string"}
+			}
+			setSliceOrArray(rv, reflect.ValueOf(b))
+			return nil
+
+		case []Number:
+			b := make([]byte, len(element))
+			for i, num := range element {
+				v, err := num.Int64()
+				if err != nil || v < 0 || v > 255 {
+					return ErrUnexpectedType{PathJoin(path, strconv.Itoa(i)), "number", "byte (0-255)"}
+				}
+				b[i] = byte(v)
+			}
+			setSliceOrArray(rv, reflect.ValueOf(b))
+			return nil
+
+		default:
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "base64 string or byte array"}
+		}
+	}
+
+	if (elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Array) && elemType.Elem().Kind() != reflect.Uint8 {
+		nodes, ok := element.([]Node)
+		if !ok {
+			return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "array of arrays"}
+		}
+
+		out := reflect.MakeSlice(reflect.SliceOf(elemType), len(nodes), len(nodes))
+		for i, inner := range nodes {
+			items := make([]interface{}, len(inner))
+			for k, v := range inner {
+				idx, err := strconv.Atoi(k)
+				if err != nil || idx < 0 || idx >= len(items) {
+					return ErrUnexpectedType{PathJoin(path, strconv.Itoa(i)), "node", "array of arrays"}
+				}
+				items[idx] = v
+			}
+
+			innerSlice := reflect.MakeSlice(elemType, len(items), len(items))
+			for j, item := range items {
+				if err := decodeValue(item, innerSlice.Index(j), PathJoin(path, strconv.Itoa(i), strconv.Itoa(j))); err != nil {
+					return err
+				}
+			}
+			setSliceOrArray(out.Index(i), innerSlice)
+		}
+
+		setSliceOrArray(rv, out)
+		return nil
+	}
+
+	list := reflect.ValueOf(element)
+	if list.Kind() != reflect.Slice {
+		return ErrUnexpectedType{path, fmt.Sprintf("%T", element), "array"}
+	}
+
+	out := reflect.MakeSlice(reflect.SliceOf(elemType), list.Len(), list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if err := decodeValue(list.Index(i).Interface(), out.Index(i), PathJoin(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+
+	setSliceOrArray(rv, out)
+	return nil
+}
+
+// setSliceOrArray assigns slice to rv, copying element-by-element if rv is a fixed-size array.
+func setSliceOrArray(rv reflect.Value, slice reflect.Value) {
+	if rv.Kind() == reflect.Array {
+		reflect.Copy(rv, slice)
+		return
+	}
+	rv.Set(slice)
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+type structTestInner struct {
+	Name string `cdb:"name"`
+}
+
+type structTestEmbedded struct {
+	Label string `cdb:"label"`
+}
+
+type structTestOuter struct {
+	structTestEmbedded
+
+	F         float64           `cdb:"someFloat"`
+	Tags      []string          `cdb:"tags,omitempty"`
+	Inner     structTestInner   `cdb:"inner"`
+	Items     []structTestInner `cdb:"items"`
+	Matrix    [][]string        `cdb:"matrix"`
+	Raw       []byte            `cdb:"raw,raw"`
+	Encoded   []byte            `cdb:"encoded"`
+	Skipped   string            `cdb:"-"`
+	unexposed string
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	in := structTestOuter{
+		structTestEmbedded: structTestEmbedded{Label: "outer"},
+		F:                  3.5,
+		Inner:              structTestInner{Name: "inner"},
+		Items:              []structTestInner{{Name: "a"}, {Name: "b"}},
+		Matrix:             [][]string{{"x", "y"}, {"z"}},
+		Raw:                []byte{1, 2, 3},
+		Encoded:            []byte("hi"),
+		Skipped:            "ignored",
+	}
+
+	n := Node{}
+	if err := n.SetStruct("root", in); err != nil {
+		t.Fatalf("SetStruct() failed: %v", err)
+	}
+
+	root, ok := n.Get("root").(Node)
+	if !ok {
+		t.Fatalf("root has unexpected type %T", n.Get("root"))
+	}
+
+	// "-" fields must not be written, and unexported fields are skipped.
+	if _, ok := root["Skipped"]; ok {
+		t.Errorf("field tagged \"-\" was written to the tree")
+	}
+	if _, ok := root["unexposed"]; ok {
+		t.Errorf("unexported field was written to the tree")
+	}
+
+	// omitempty must drop a zero-value slice.
+	if _, ok := root["tags"]; ok {
+		t.Errorf("empty omitempty field was written to the tree")
+	}
+
+	// Embedded struct fields are promoted into the parent node.
+	if got := root.GetString("label", ""); got != "outer" {
+		t.Errorf("label = %q, want %q", got, "outer")
+	}
+
+	var out structTestOuter
+	if err := n.GetStruct("root", &out); err != nil {
+		t.Fatalf("GetStruct() failed: %v", err)
+	}
+
+	out.Skipped = "ignored" // Not round-tripped, set it back for the comparison below.
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("GetStruct() = %+v, want %+v", out, in)
+	}
+}
+
+func TestStructGetUnexpectedType(t *testing.T) {
+	n := Node{}
+	if err := n.Set("value", "a string"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	var out int
+	err := n.GetStruct("value", &out)
+	if _, ok := err.(ErrUnexpectedType); !ok {
+		t.Fatalf("GetStruct() error = %v (%T), want ErrUnexpectedType", err, err)
+	}
+}
+
+type structTestByteScalar struct {
+	Flag uint8 `cdb:"flag"`
+}
+
+func TestStructByteScalarRoundTrip(t *testing.T) {
+	in := structTestByteScalar{Flag: 42}
+
+	n := Node{}
+	if err := n.SetStruct("root", in); err != nil {
+		t.Fatalf("SetStruct() failed: %v", err)
+	}
+
+	var out structTestByteScalar
+	if err := n.GetStruct("root", &out); err != nil {
+		t.Fatalf("GetStruct() failed: %v", err)
+	}
+
+	if out != in {
+		t.Errorf("GetStruct() = %+v, want %+v", out, in)
+	}
+}
+
+func TestStructBytesRawArray(t *testing.T) {
+	n := Node{}
+	if err := n.Set("raw", []Number{Number("1"), Number("2"), Number("3")}); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	var out []byte
+	if err := n.GetStruct("raw", &out); err != nil {
+		t.Fatalf("GetStruct() failed: %v", err)
+	}
+
+	want := []byte{1, 2, 3}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("GetStruct() = %v, want %v", out, want)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Dadido3/configdb/tree"
+)
+
+// defaultReloadDebounce is the debounce window ScheduleReload uses when SetReloadDebounce has not been
+// called.
+const defaultReloadDebounce = 200 * time.Millisecond
+
+// ChangeKind describes the kind of change a ChangeEvent represents.
+type ChangeKind int
+
+const (
+	// ChangeModified indicates that the value at Path was modified.
+	ChangeModified ChangeKind = iota
+	// ChangeAdded indicates that a new element was added at Path.
+	ChangeAdded
+	// ChangeRemoved indicates that the element at Path was removed.
+	ChangeRemoved
+)
+
+// ChangeEvent describes a single change inside the merged configuration tree.
+type ChangeEvent struct {
+	Kind ChangeKind
+	Path string
+
+	OldValue interface{} // nil for ChangeAdded.
+	NewValue interface{} // nil for ChangeRemoved.
+}
+
+// subscription is a single Watch registration.
+type subscription struct {
+	path string
+	cb   func(ChangeEvent)
+
+	queue     chan ChangeEvent
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// run delivers queued events to cb, one at a time and in order, until the subscription is closed.
+func (sub *subscription) run() {
+	for {
+		select {
+		case event := <-sub.queue:
+			sub.cb(event)
+		case <-sub.closeCh:
+			return
+		}
+	}
+}
+
+// stop closes the subscription's channel, safe to call more than once and from Config.Close as well as
+// the unsubscribe function returned by Watch.
+func (sub *subscription) stop() {
+	sub.closeOnce.Do(func() {
+		close(sub.closeCh)
+	})
+}
+
+// Watch registers cb to be called whenever something at or below path changes.
+//
+// Watching "" (or any path pointing at the root) delivers every change in the tree. Each subscription
+// gets its own goroutine, so callbacks for a given subscription always run one at a time and in order,
+// even if a callback is slow; a burst of events is queued rather than reordered. Each subscription
+// queues at most 64 pending events; events arriving while its queue is full are dropped.
+//
+// The returned unsubscribe function stops the subscription and is safe to call more than once.
+func (c *Config) Watch(path string, cb func(event ChangeEvent)) (unsubscribe func(), err error) {
+	sub := &subscription{
+		path:    path,
+		cb:      cb,
+		queue:   make(chan ChangeEvent, 64),
+		closeCh: make(chan struct{}),
+	}
+	go sub.run()
+
+	c.watchMutex.Lock()
+	c.watchers = append(c.watchers, sub)
+	c.watchMutex.Unlock()
+
+	unsubscribe = func() {
+		c.watchMutex.Lock()
+		for i, s := range c.watchers {
+			if s == sub {
+				c.watchers = append(c.watchers[:i], c.watchers[i+1:]...)
+				break
+			}
+		}
+		c.watchMutex.Unlock()
+
+		sub.stop()
+	}
+
+	return unsubscribe, nil
+}
+
+// Reload re-reads every backing File, merges them, and notifies watchers of anything that changed.
+func (c *Config) Reload() error {
+	merged, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	old := c.tree
+	c.tree = merged
+	new := cloneNode(merged) // Clone while still under the lock, so notify can diff it without racing a concurrent Set/Reload.
+	c.mutex.Unlock()
+
+	c.notify(old, new)
+
+	return nil
+}
+
+// ScheduleReload debounces Reload: a burst of calls within the debounce window collapses into a single
+// reload and diff pass, run after the window elapses without a further call.
+//
+// Use this instead of calling Reload directly when reloads are triggered by something that can fire in
+// bursts, such as a file watcher reacting to an editor that saves a file via multiple temp-file renames.
+// The default debounce window is 200ms; change it with SetReloadDebounce.
+func (c *Config) ScheduleReload() {
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+
+	debounce := c.reloadDebounce
+	if debounce == 0 {
+		debounce = defaultReloadDebounce
+	}
+
+	if c.reloadTimer != nil {
+		c.reloadTimer.Reset(debounce)
+		return
+	}
+
+	c.reloadTimer = time.AfterFunc(debounce, func() {
+		c.watchMutex.Lock()
+		c.reloadTimer = nil
+		c.watchMutex.Unlock()
+
+		_ = c.Reload()
+	})
+}
+
+// SetReloadDebounce sets the debounce window used by ScheduleReload. The default is 200ms.
+func (c *Config) SetReloadDebounce(d time.Duration) {
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+
+	c.reloadDebounce = d
+}
+
+// notify diffs old against new and fans the resulting changes out to every matching subscriber.
+func (c *Config) notify(old, new tree.Node) {
+	modified, added, removed := old.Compare(new)
+	if len(modified) == 0 && len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	c.watchMutex.RLock()
+	defer c.watchMutex.RUnlock()
+
+	for _, path := range modified {
+		c.dispatch(old, new, path, ChangeModified)
+	}
+	for _, path := range added {
+		c.dispatch(old, new, path, ChangeAdded)
+	}
+	for _, path := range removed {
+		c.dispatch(old, new, path, ChangeRemoved)
+	}
+}
+
+// dispatch builds the ChangeEvent for path and delivers it to every subscriber whose watched path is
+// an ancestor of (or equal to) it.
+func (c *Config) dispatch(old, new tree.Node, path string, kind ChangeKind) {
+	event := ChangeEvent{
+		Kind:     kind,
+		Path:     path,
+		OldValue: old.Get(path),
+		NewValue: new.Get(path),
+	}
+
+	for _, sub := range c.watchers {
+		if !pathCovers(sub.path, path) {
+			continue
+		}
+
+		select {
+		case sub.queue <- event:
+		default:
+			// Subscriber is falling behind; drop the event rather than block the reload/Set path.
+		}
+	}
+}
+
+// pathCovers reports whether watchPath is an ancestor of (or equal to) changedPath.
+func pathCovers(watchPath, changedPath string) bool {
+	watchElements := tree.PathSplit(watchPath)
+	if len(watchElements) == 0 {
+		return true // Root watches everything.
+	}
+
+	changedElements := tree.PathSplit(changedPath)
+	if len(changedElements) < len(watchElements) {
+		return false
+	}
+
+	for i, e := range watchElements {
+		if changedElements[i] != e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cloneNode makes a deep copy of n, so it can be diffed against after n has been mutated in place.
+func cloneNode(n tree.Node) tree.Node {
+	out := make(tree.Node, len(n))
+	for k, v := range n {
+		switch v := v.(type) {
+		case tree.Node:
+			out[k] = cloneNode(v)
+		case []tree.Node:
+			nodes := make([]tree.Node, len(v))
+			for i, child := range v {
+				nodes[i] = cloneNode(child)
+			}
+			out[k] = nodes
+		default:
+			out[k] = v
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2019 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package configdb
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dadido3/configdb/tree"
+)
+
+type memFile struct {
+	mutex sync.Mutex
+	node  tree.Node
+}
+
+func (f *memFile) Load() (tree.Node, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.node, nil
+}
+
+func (f *memFile) Save(n tree.Node) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.node = n
+	return nil
+}
+
+// drainEvents collects whatever events arrive on the channel within a short window.
+func drainEvents(events chan ChangeEvent) []ChangeEvent {
+	var got []ChangeEvent
+	deadline := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		case <-deadline:
+			return got
+		}
+	}
+}
+
+func TestWatchSet(t *testing.T) {
+	c, err := NewConfig([]File{&memFile{node: tree.Node{}}})
+	if err != nil {
+		t.Fatalf("NewConfig() failed: %v", err)
+	}
+	defer c.Close()
+
+	events := make(chan ChangeEvent, 8)
+	unsubscribe, err := c.Watch("subnode", func(event ChangeEvent) {
+		events <- event
+	})
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	defer unsubscribe()
+
+	if err := c.Set("subnode.value", "a"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	got := drainEvents(events)
+
+	var found bool
+	for _, event := range got {
+		if event.Path == "subnode.value" {
+			found = true
+			if event.Kind != ChangeAdded {
+				t.Errorf("event.Kind = %v, want ChangeAdded", event.Kind)
+			}
+			if event.NewValue != "a" {
+				t.Errorf("event.NewValue = %v, want %q", event.NewValue, "a")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no event for subnode.value, got %+v", got)
+	}
+
+	if err := c.Set("other.value", "b"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	if got := drainEvents(events); len(got) != 0 {
+		t.Errorf("received unexpected events for unwatched path: %+v", got)
+	}
+}
+
+func TestWatchUnsubscribe(t *testing.T) {
+	c, err := NewConfig([]File{&memFile{node: tree.Node{}}})
+	if err != nil {
+		t.Fatalf("NewConfig() failed: %v", err)
+	}
+	defer c.Close()
+
+	events := make(chan ChangeEvent, 8)
+	unsubscribe, err := c.Watch("", func(event ChangeEvent) {
+		events <- event
+	})
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+
+	unsubscribe()
+	unsubscribe() // Must be safe to call more than once.
+
+	if err := c.Set("value", "a"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("received event after unsubscribe: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScheduleReloadDebounce(t *testing.T) {
+	file := &memFile{node: tree.Node{}}
+	c, err := NewConfig([]File{file})
+	if err != nil {
+		t.Fatalf("NewConfig() failed: %v", err)
+	}
+	defer c.Close()
+	c.SetReloadDebounce(20 * time.Millisecond)
+
+	events := make(chan ChangeEvent, 8)
+	unsubscribe, err := c.Watch("", func(event ChangeEvent) {
+		events <- event
+	})
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	defer unsubscribe()
+
+	// A burst of reload requests within the debounce window must collapse into a single reload.
+	if err := file.Save(tree.Node{"value": "a"}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		c.ScheduleReload()
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	got := drainEvents(events)
+	var count int
+	for _, event := range got {
+		if event.Path == "value" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("got %d events for value, want exactly 1 (burst should coalesce)", count)
+	}
+}
+
+func TestWatchConcurrentSet(t *testing.T) {
+	c, err := NewConfig([]File{&memFile{node: tree.Node{}}})
+	if err != nil {
+		t.Fatalf("NewConfig() failed: %v", err)
+	}
+	defer c.Close()
+
+	unsubscribe, err := c.Watch("", func(ChangeEvent) {})
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = c.Set("counter", i)
+		}(i)
+	}
+	wg.Wait()
+}